@@ -0,0 +1,125 @@
+package serr
+
+import (
+	"runtime"
+	"sync"
+)
+
+// StackCapturer captures the calling goroutine's program counters, in the
+// same skip/pc sense as runtime.Callers. Swap the process-wide default with
+// SetDefaultCapturer, or override it for a single New/Wrap call with
+// WithCapturer, to trade stack-trace depth for the CPU/allocation cost of
+// capturing it on hot paths.
+type StackCapturer interface {
+	Capture(skip int) []uintptr
+}
+
+// FixedDepthCapturer captures at most n frames with a single runtime.Callers
+// call. This is what serr did unconditionally before StackCapturer existed
+// (n=64), and remains the default.
+type FixedDepthCapturer int
+
+func (c FixedDepthCapturer) Capture(skip int) []uintptr {
+	pcs := make([]uintptr, int(c))
+	n := runtime.Callers(skip, pcs)
+	return pcs[:n]
+}
+
+const adaptiveCapturerMaxDepth = 1024
+
+// adaptiveCapturer starts with a small buffer and only pays for a second,
+// larger runtime.Callers call on the rare stack deep enough to overflow it.
+type adaptiveCapturer struct {
+	initialDepth int
+}
+
+// AdaptiveCapturer returns a StackCapturer that starts with an 8-frame
+// buffer and doubles it (up to a 1024-frame ceiling) whenever the stack
+// overflows the previous attempt.
+func AdaptiveCapturer() StackCapturer {
+	return &adaptiveCapturer{initialDepth: 8}
+}
+
+func (c *adaptiveCapturer) Capture(skip int) []uintptr {
+	depth := c.initialDepth
+	for {
+		pcs := make([]uintptr, depth)
+		n := runtime.Callers(skip, pcs)
+		if n < depth || depth >= adaptiveCapturerMaxDepth {
+			return pcs[:n]
+		}
+		depth *= 2
+	}
+}
+
+// disabledCapturer records only the immediate caller, skipping the
+// potentially deep runtime.Callers walk entirely.
+type disabledCapturer struct{}
+
+// DisabledCapturer returns a StackCapturer that records only the immediate
+// caller for ExtraStackData, for hot paths where a full stack trace isn't
+// worth its cost. Errors built from it only ever report a single frame.
+func DisabledCapturer() StackCapturer {
+	return disabledCapturer{}
+}
+
+func (disabledCapturer) Capture(skip int) []uintptr {
+	var pcs [1]uintptr
+	n := runtime.Callers(skip, pcs[:])
+	return pcs[:n]
+}
+
+var (
+	defaultCapturerMu sync.RWMutex
+	defaultCapturer   StackCapturer = FixedDepthCapturer(64)
+)
+
+// SetDefaultCapturer replaces the StackCapturer used by New, Errorf, Errors,
+// Wrap, Wrapf, Wraps, Join, and their Depths/Coded variants. Only New and
+// Wrap can override it per call with WithCapturer; every other variant
+// always uses whatever SetDefaultCapturer last installed.
+func SetDefaultCapturer(capturer StackCapturer) {
+	defaultCapturerMu.Lock()
+	defer defaultCapturerMu.Unlock()
+	defaultCapturer = capturer
+}
+
+func getDefaultCapturer() StackCapturer {
+	defaultCapturerMu.RLock()
+	defer defaultCapturerMu.RUnlock()
+	return defaultCapturer
+}
+
+// Option configures a single New or Wrap call.
+type Option func(*options)
+
+type options struct {
+	capturer StackCapturer
+}
+
+// WithCapturer overrides the StackCapturer for a single New/Wrap call
+// instead of changing the process-wide default. Only New and Wrap accept
+// Option: their other variants (Errorf, Errors, Wrapf, Wraps, Join, every
+// *Depth*/*Coded* flavor, ...) already spend their trailing variadic
+// parameter on msg/field args, so there's no room for ...Option alongside
+// it; those calls always go through the process-wide default capturer set
+// by SetDefaultCapturer.
+func WithCapturer(capturer StackCapturer) Option {
+	return func(o *options) {
+		o.capturer = capturer
+	}
+}
+
+func resolveCapturer(opts []Option) StackCapturer {
+	o := options{capturer: getDefaultCapturer()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.capturer
+}
+
+// locationCache caches resolved *Location values keyed by pc, since repeated
+// formatting of the same error (common when it bubbles through logging,
+// metrics, and tracing) would otherwise re-run runtime.CallersFrames's
+// symbolication for the same pc every time.
+var locationCache sync.Map // uintptr -> *Location
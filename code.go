@@ -0,0 +1,134 @@
+package serr
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+)
+
+// Code is a small error-category code that can be compared across package
+// boundaries without sharing sentinel error values. Register a
+// human-readable name for it with RegisterCode, typically from an init func
+// alongside the constant it names.
+type Code int32
+
+var (
+	codeNamesMu sync.RWMutex
+	codeNames   = map[Code]string{}
+)
+
+// RegisterCode associates name with code so Code.Error() and ToJSON/ToString
+// render something more useful than the raw integer.
+func RegisterCode(code Code, name string) {
+	codeNamesMu.Lock()
+	defer codeNamesMu.Unlock()
+	codeNames[code] = name
+}
+
+// Error makes Code itself satisfy the error interface, so
+// errors.Is(err, MyCode) works directly against a chain produced by
+// NewCoded/WrapCoded.
+func (c Code) Error() string {
+	codeNamesMu.RLock()
+	name, ok := codeNames[c]
+	codeNamesMu.RUnlock()
+
+	if ok {
+		return name
+	}
+
+	return "code(" + strconv.Itoa(int(c)) + ")"
+}
+
+func NewCoded(code Code, msg string) error {
+	return ErrorDepthsCoded(1, code, nil, msg)
+}
+
+func ErrorfCoded(code Code, msg string, args ...interface{}) error {
+	return ErrorDepthsCoded(1, code, nil, msg, args...)
+}
+
+func ErrorsCoded(code Code, fields map[string]interface{}, msg string, args ...interface{}) error {
+	return ErrorDepthsCoded(1, code, fields, msg, args...)
+}
+
+func ErrorDepthsCoded(skip int, code Code, fields map[string]interface{}, msg string, args ...interface{}) error {
+	rootErr := ErrorDepthsWrapError(skip+1, nil, fields, msg, args...).(*rootError)
+	rootErr.extraStackData.code = &code
+	return rootErr
+}
+
+func WrapCoded(err error, code Code) error {
+	return WrapDepthsCoded(1, err, code, nil, "")
+}
+
+func WrapfCoded(err error, code Code, msg string, msgArgs ...interface{}) error {
+	return WrapDepthsCoded(1, err, code, nil, msg, msgArgs...)
+}
+
+func WrapsCoded(err error, code Code, fields map[string]interface{}, msg string, msgArgs ...interface{}) error {
+	return WrapDepthsCoded(1, err, code, fields, msg, msgArgs...)
+}
+
+func WrapDepthsCoded(skip int, err error, code Code, fields map[string]interface{}, msg string, msgArgs ...interface{}) error {
+	wrapped := WrapDepths(skip+1, err, fields, msg, msgArgs...)
+
+	switch w := wrapped.(type) {
+	case *wrapError:
+		w.extraStackData.code = &code
+	case *rootError:
+		w.extraStackData.code = &code
+	}
+
+	return wrapped
+}
+
+// CodeOf walks err's chain and returns the innermost explicit Code set via
+// NewCoded/WrapCoded, or ok=false if none is set. It only follows
+// Unwrap() error, so it doesn't look inside a Join's branches; use Is to
+// check whether any code anywhere in the chain, Join branches included,
+// matches a particular Code.
+func CodeOf(err error) (code Code, ok bool) {
+	for err != nil {
+		if e, has := err.(ExtraStackError); has {
+			if data := e.GetExtraStackData(); data != nil && data.code != nil {
+				code = *data.code
+				ok = true
+			}
+		}
+
+		uerr, has := err.(interface{ Unwrap() error })
+		if !has {
+			break
+		}
+		err = uerr.Unwrap()
+	}
+
+	return code, ok
+}
+
+// Is reports whether any Code in err's chain equals code, descending into
+// Join branches the same way errors.Is does. It's exactly errors.Is(err,
+// code), exposed so callers that don't want to import "errors" themselves
+// can spell it serr.Is. Unlike CodeOf, it isn't limited to the innermost
+// explicit code.
+func Is(err error, code Code) bool {
+	return errors.Is(err, code)
+}
+
+func (e *rootError) Is(target error) bool {
+	return codeMatches(e.extraStackData, target)
+}
+
+func (e *wrapError) Is(target error) bool {
+	return codeMatches(e.extraStackData, target)
+}
+
+func codeMatches(data *ExtraStackData, target error) bool {
+	targetCode, ok := target.(Code)
+	if !ok || data == nil || data.code == nil {
+		return false
+	}
+
+	return *data.code == targetCode
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -13,24 +14,41 @@ type Location struct {
 	Filename string
 	Line     int
 	FuncName string
+
+	// Preformatted, if non-empty, is already-rendered location text (e.g.
+	// a frame reconstructed from a remote source like serr/grpcstatus that
+	// only has the formatted string, not the original Filename/Line/
+	// FuncName). DefaultLocationFormatFunc returns it verbatim instead of
+	// re-deriving text from the other fields.
+	Preformatted string
 }
 
+// getLocation resolves pc to a *Location, caching the result since the same
+// pc is often formatted repeatedly as an error bubbles through logging,
+// metrics, and tracing.
 func getLocation(pc uintptr) *Location {
 	if pc == 0 {
 		return &Location{}
 	}
 
+	if cached, ok := locationCache.Load(pc); ok {
+		return cached.(*Location)
+	}
+
 	frames := runtime.CallersFrames([]uintptr{pc})
 	frame, _ := frames.Next()
 
 	i := strings.LastIndex(frame.Function, "/")
 	name := frame.Function[i+1:]
 
-	return &Location{
+	loc := &Location{
 		Filename: frame.File,
 		Line:     frame.Line,
 		FuncName: name,
 	}
+
+	actual, _ := locationCache.LoadOrStore(pc, loc)
+	return actual.(*Location)
 }
 
 type WrapLink struct {
@@ -38,6 +56,23 @@ type WrapLink struct {
 	MsgArgs        []interface{}
 	Fields         map[string]interface{}
 	CallerLocation *Location
+	Code           *Code
+
+	// Preformatted marks Msg as already-rendered text (e.g. a link
+	// reconstructed from a remote source like serr/grpcstatus that only
+	// has the final message, not the original format string/MsgArgs), so
+	// renderers use it verbatim instead of re-running fmt.Sprintf on it.
+	Preformatted bool
+}
+
+// formatLinkMsg renders link.Msg, applying MsgArgs unless link.Preformatted
+// says Msg is already-substituted text that would corrupt on a second pass
+// (e.g. a literal '%' in a message reconstructed by serr/grpcstatus).
+func formatLinkMsg(link *WrapLink) string {
+	if link.Preformatted {
+		return link.Msg
+	}
+	return fmt.Sprintf(link.Msg, link.MsgArgs...)
 }
 
 type UnpackHierarchy struct {
@@ -59,11 +94,38 @@ func (o *UnpackHierarchy) addCallerLocation(callerLocation *Location) {
 	o.CallerLocations = append(o.CallerLocations, callerLocation)
 }
 
+// HierarchyProvider lets an error type supply its own *UnpackHierarchy
+// directly instead of having one derived from Callers()/ExtraStackData.
+// unpack checks for it before falling back to the normal PC-based walk, so
+// errors reconstructed from an out-of-process representation (see
+// serr/grpcstatus) render through ToJSON/ToString exactly like local ones.
+type HierarchyProvider interface {
+	error
+	UnpackHierarchy() *UnpackHierarchy
+}
+
 func Unpack(err error) *UnpackHierarchy {
 	return unpack(err, 0)
 }
 
+// matchesCallerIndex reports whether the wrap link recorded with the given
+// callerCaller belongs at callerIndex. Normally that means
+// callers[callerIndex+1] is that link's caller-of-caller; but a capturer
+// that records fewer than 2 frames (e.g. DisabledCapturer) never sets
+// callerCaller, so at the outermost index a zero callerCaller matches
+// directly instead of never matching.
+func matchesCallerIndex(callers []uintptr, callerIndex int, callerCaller uintptr) bool {
+	if callerIndex == len(callers)-1 {
+		return callerCaller == 0
+	}
+	return callers[callerIndex+1] == callerCaller
+}
+
 func unpack(err error, parentPC uintptr) *UnpackHierarchy {
+	if provider, ok := err.(HierarchyProvider); ok {
+		return provider.UnpackHierarchy()
+	}
+
 	fullStackErr := Cause(err)
 	if fullStackErr == nil {
 		return &UnpackHierarchy{
@@ -95,15 +157,16 @@ func unpack(err error, parentPC uintptr) *UnpackHierarchy {
 
 	hierarchy := &UnpackHierarchy{}
 	for callerIndex := callerStartIndex; callerIndex >= 0; callerIndex-- {
-		for extraStackErr != nil && callerIndex != len(callers)-1 && callers[callerIndex+1] == extraStackErr.GetExtraStackData().callerCaller {
+		for extraStackErr != nil && matchesCallerIndex(callers, callerIndex, extraStackErr.GetExtraStackData().callerCaller) {
 			extraStackData := extraStackErr.GetExtraStackData()
 			callerLocation := getLocation(extraStackData.caller)
-			if len(extraStackData.msg) != 0 || len(extraStackData.msgArgs) != 0 || len(extraStackData.fields) != 0 {
+			if len(extraStackData.msg) != 0 || len(extraStackData.msgArgs) != 0 || len(extraStackData.fields) != 0 || extraStackData.code != nil {
 				hierarchy.Links = append(hierarchy.Links, &WrapLink{
 					Msg:            extraStackData.msg,
 					MsgArgs:        extraStackData.msgArgs,
 					Fields:         extraStackData.fields,
 					CallerLocation: callerLocation,
+					Code:           extraStackData.code,
 				})
 			}
 			hierarchy.addCallerLocation(callerLocation)
@@ -114,11 +177,18 @@ func unpack(err error, parentPC uintptr) *UnpackHierarchy {
 		hierarchy.addCallerLocation(getLocation(callers[callerIndex]))
 	}
 
+	// A capturer that records fewer than 2 frames (e.g. DisabledCapturer)
+	// has no parent PC to hand to the child unpack; 0 means "don't trim".
+	var childParentPC uintptr
+	if len(callers) > 1 {
+		childParentPC = callers[1]
+	}
+
 	if uerr, ok := fullStackErr.(interface{ Unwrap() error }); ok {
-		hierarchy.SubHierarchies = append(hierarchy.SubHierarchies, unpack(uerr.Unwrap(), callers[1]))
+		hierarchy.SubHierarchies = append(hierarchy.SubHierarchies, unpack(uerr.Unwrap(), childParentPC))
 	} else if uerr, ok := fullStackErr.(interface{ Unwrap() []error }); ok {
 		for _, e := range uerr.Unwrap() {
-			hierarchy.SubHierarchies = append(hierarchy.SubHierarchies, unpack(e, callers[1]))
+			hierarchy.SubHierarchies = append(hierarchy.SubHierarchies, unpack(e, childParentPC))
 		}
 	}
 
@@ -133,12 +203,71 @@ func unpack(err error, parentPC uintptr) *UnpackHierarchy {
 	return hierarchy
 }
 
+// FramePredicate reports whether a frame should be kept when rendering a
+// stack trace. Returning false suppresses it.
+type FramePredicate func(frame *Location) bool
+
+// DefaultFramePredicate drops frames belonging to the Go runtime and the
+// testing harness's run loop, which never carry useful information about
+// where an error actually occurred.
+func DefaultFramePredicate(frame *Location) bool {
+	return !strings.HasPrefix(frame.FuncName, "runtime.") && !strings.HasPrefix(frame.FuncName, "testing.")
+}
+
+// NewRegexFramePredicate returns a FramePredicate that suppresses any frame
+// whose FuncName matches pattern, e.g. generated code or middleware.
+func NewRegexFramePredicate(pattern string) (FramePredicate, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(frame *Location) bool {
+		return !re.MatchString(frame.FuncName)
+	}, nil
+}
+
+// resolveFramePredicate returns predicate, or DefaultFramePredicate if nil.
+func resolveFramePredicate(predicate FramePredicate) FramePredicate {
+	if predicate == nil {
+		return DefaultFramePredicate
+	}
+	return predicate
+}
+
+// compactLocations applies predicate (DefaultFramePredicate if nil) and
+// collapses consecutive identical (file,line,func) frames. It runs at
+// render time rather than inside unpack so that the underlying
+// UnpackHierarchy stays reusable across formats with different predicates.
+func compactLocations(locations []*Location, predicate FramePredicate) []*Location {
+	if predicate == nil {
+		predicate = DefaultFramePredicate
+	}
+
+	compacted := make([]*Location, 0, len(locations))
+	for _, loc := range locations {
+		if !predicate(loc) {
+			continue
+		}
+		if len(compacted) > 0 && *compacted[len(compacted)-1] == *loc {
+			continue
+		}
+		compacted = append(compacted, loc)
+	}
+
+	return compacted
+}
+
 type FormatOptions struct {
 	LocationFormatFunc func(frame *Location) string
 	WithTrace          bool
+	FramePredicate     FramePredicate
 }
 
 func DefaultLocationFormatFunc(frame *Location) string {
+	if frame.Preformatted != "" {
+		return frame.Preformatted
+	}
 	return frame.Filename + ":" + strconv.Itoa(frame.Line) + "(" + frame.FuncName + ")"
 }
 
@@ -147,6 +276,9 @@ type JSONFormat struct {
 }
 
 func NewDefaultJSONFormat(options FormatOptions) JSONFormat {
+	if options.FramePredicate == nil {
+		options.FramePredicate = DefaultFramePredicate
+	}
 	return JSONFormat{
 		Options: options,
 	}
@@ -165,25 +297,33 @@ func ToCustomJSON(err error, format JSONFormat) interface{} {
 }
 
 func toCustomJSON(hierarchy *UnpackHierarchy, format JSONFormat) interface{} {
+	predicate := resolveFramePredicate(format.Options.FramePredicate)
+
 	root := map[string]interface{}{}
-	if format.Options.WithTrace && len(hierarchy.CallerLocations) > 0 {
-		stackArr := []string{}
-		for _, stack := range hierarchy.CallerLocations {
-			src := format.Options.LocationFormatFunc(stack)
-			stackArr = append(stackArr, src)
+	if format.Options.WithTrace {
+		locations := compactLocations(hierarchy.CallerLocations, predicate)
+		if len(locations) > 0 {
+			stackArr := []string{}
+			for _, stack := range locations {
+				src := format.Options.LocationFormatFunc(stack)
+				stackArr = append(stackArr, src)
+			}
+			root["stack"] = stackArr
 		}
-		root["stack"] = stackArr
 	}
 	if len(hierarchy.Links) > 0 {
 		wrapArr := []interface{}{}
 		for _, link := range hierarchy.Links {
 			wrapMap := map[string]interface{}{
-				"msg": fmt.Sprintf(link.Msg, link.MsgArgs...),
+				"msg": formatLinkMsg(link),
 			}
 			if len(link.Fields) != 0 {
 				wrapMap["fields"] = link.Fields
 			}
-			if format.Options.WithTrace {
+			if link.Code != nil {
+				wrapMap["code"] = link.Code.Error()
+			}
+			if format.Options.WithTrace && predicate(link.CallerLocation) {
 				wrapMap["src"] = format.Options.LocationFormatFunc(link.CallerLocation)
 			}
 			wrapArr = append(wrapArr, wrapMap)
@@ -221,6 +361,9 @@ type StringFormat struct {
 }
 
 func NewDefaultStringFormat(options FormatOptions) StringFormat {
+	if options.FramePredicate == nil {
+		options.FramePredicate = DefaultFramePredicate
+	}
 	format := StringFormat{
 		Options:         options,
 		FieldFormatFunc: DefaultFieldFormat,
@@ -249,28 +392,34 @@ func ToCustomString(err error, format StringFormat) string {
 }
 
 func toCustomString(hierarchy *UnpackHierarchy, format StringFormat, level int) string {
+	predicate := resolveFramePredicate(format.Options.FramePredicate)
+	locations := compactLocations(hierarchy.CallerLocations, predicate)
+
 	str := ""
 	stackIndex := 0
 	for _, link := range hierarchy.Links {
-		if format.Options.WithTrace {
-			if stackIndex == 0 || *hierarchy.CallerLocations[stackIndex-1] != *link.CallerLocation {
-				for stackIndex < len(hierarchy.CallerLocations)-1 && *hierarchy.CallerLocations[stackIndex] != *link.CallerLocation {
-					str += strings.Repeat(format.PreStackSep, level) + format.Options.LocationFormatFunc(hierarchy.CallerLocations[stackIndex]) + format.StackElemSep
+		if format.Options.WithTrace && predicate(link.CallerLocation) {
+			if stackIndex == 0 || *locations[stackIndex-1] != *link.CallerLocation {
+				for stackIndex < len(locations)-1 && *locations[stackIndex] != *link.CallerLocation {
+					str += strings.Repeat(format.PreStackSep, level) + format.Options.LocationFormatFunc(locations[stackIndex]) + format.StackElemSep
 					stackIndex++
 				}
 				str += strings.Repeat(format.PreStackSep, level) + format.Options.LocationFormatFunc(link.CallerLocation) + format.MsgStackSep
 				stackIndex++
 			}
 		}
-		str += fmt.Sprintf(link.Msg, link.MsgArgs...)
+		str += formatLinkMsg(link)
+		if link.Code != nil {
+			str += " [" + link.Code.Error() + "]"
+		}
 		if len(link.Fields) != 0 {
 			str += strings.Repeat(format.PreStackSep, level+1) + format.FieldFormatFunc(link.Fields)
 		}
 		str += format.ErrorSep
 	}
 	if format.Options.WithTrace {
-		for stackIndex < len(hierarchy.CallerLocations) {
-			str += strings.Repeat(format.PreStackSep, level) + format.Options.LocationFormatFunc(hierarchy.CallerLocations[stackIndex]) + format.StackElemSep
+		for stackIndex < len(locations) {
+			str += strings.Repeat(format.PreStackSep, level) + format.Options.LocationFormatFunc(locations[stackIndex]) + format.StackElemSep
 			stackIndex++
 		}
 	}
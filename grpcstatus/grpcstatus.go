@@ -0,0 +1,166 @@
+// Package grpcstatus converts serr error chains to and from
+// google.golang.org/grpc/status.Status so services built on grpc-go can
+// propagate serr context across RPC boundaries.
+package grpcstatus
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/MinamiKotoriCute/serr"
+)
+
+// FieldGRPCCode is the Errors/Wraps fields key used to pick the status code
+// for the outermost wrap link. If absent, ToStatus uses codes.Unknown.
+const FieldGRPCCode = "grpc_code"
+
+// ToStatus converts err into a *status.Status. The outermost WrapLink's
+// message becomes the status message, FieldGRPCCode on that link's fields
+// selects the code, and the flattened Links (msg + fields + CallerLocation)
+// plus CallerLocations are attached as google.rpc.ErrorInfo/DebugInfo
+// details so a peer can rebuild the hierarchy with FromStatus.
+func ToStatus(err error) (*status.Status, error) {
+	if err == nil {
+		return status.New(codes.OK, ""), nil
+	}
+
+	hierarchy := serr.Unpack(err)
+
+	code := codes.Unknown
+	msg := err.Error()
+	if len(hierarchy.Links) > 0 {
+		outer := hierarchy.Links[0]
+		msg = outer.Msg
+		if !outer.Preformatted {
+			msg = fmt.Sprintf(outer.Msg, outer.MsgArgs...)
+		}
+		if c, ok := codeFromFields(outer.Fields); ok {
+			code = c
+		}
+	}
+
+	debugInfo := &errdetails.DebugInfo{}
+	for _, loc := range hierarchy.CallerLocations {
+		debugInfo.StackEntries = append(debugInfo.StackEntries, serr.DefaultLocationFormatFunc(loc))
+	}
+
+	errInfo := &errdetails.ErrorInfo{Reason: "SERR_WRAP_CHAIN", Metadata: map[string]string{}}
+	for i, link := range hierarchy.Links {
+		prefix := "wrap." + strconv.Itoa(i) + "."
+		if link.Preformatted {
+			errInfo.Metadata[prefix+"msg"] = link.Msg
+		} else {
+			errInfo.Metadata[prefix+"msg"] = fmt.Sprintf(link.Msg, link.MsgArgs...)
+		}
+		if link.CallerLocation != nil {
+			errInfo.Metadata[prefix+"src"] = serr.DefaultLocationFormatFunc(link.CallerLocation)
+		}
+		for k, v := range link.Fields {
+			errInfo.Metadata[prefix+"field."+k] = fmt.Sprint(v)
+		}
+	}
+
+	return status.New(code, msg).WithDetails(debugInfo, errInfo)
+}
+
+func codeFromFields(fields map[string]interface{}) (codes.Code, bool) {
+	v, ok := fields[FieldGRPCCode]
+	if !ok {
+		return codes.Unknown, false
+	}
+
+	switch c := v.(type) {
+	case codes.Code:
+		return c, true
+	case int:
+		return codes.Code(c), true
+	case int32:
+		return codes.Code(c), true
+	default:
+		return codes.Unknown, false
+	}
+}
+
+// remoteHierarchyError is the synthetic error FromStatus hands back so
+// ToJSON/ToString can render a chain that was reconstructed from a received
+// status instead of captured locally. It supplies its hierarchy through
+// serr.HierarchyProvider; it has no program counters and does not implement
+// serr.FullStackError.
+type remoteHierarchyError struct {
+	st        *status.Status
+	hierarchy *serr.UnpackHierarchy
+}
+
+var _ serr.HierarchyProvider = (*remoteHierarchyError)(nil)
+
+func (e *remoteHierarchyError) Error() string {
+	return e.st.Message()
+}
+
+func (e *remoteHierarchyError) UnpackHierarchy() *serr.UnpackHierarchy {
+	return e.hierarchy
+}
+
+// FromStatus rebuilds a serr error chain from a *status.Status produced by
+// ToStatus, preserving the remote Links and CallerLocations. If st carries
+// none of the details ToStatus attaches (for example a plain grpc-go error),
+// FromStatus falls back to st.Err().
+func FromStatus(st *status.Status) error {
+	if st == nil || st.Code() == codes.OK {
+		return nil
+	}
+
+	hierarchy := &serr.UnpackHierarchy{}
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.DebugInfo:
+			for _, entry := range d.StackEntries {
+				hierarchy.CallerLocations = append(hierarchy.CallerLocations, &serr.Location{Preformatted: entry})
+			}
+		case *errdetails.ErrorInfo:
+			hierarchy.Links = linksFromMetadata(d.Metadata)
+		}
+	}
+
+	if len(hierarchy.Links) == 0 && len(hierarchy.CallerLocations) == 0 {
+		return st.Err()
+	}
+
+	return &remoteHierarchyError{st: st, hierarchy: hierarchy}
+}
+
+func linksFromMetadata(metadata map[string]string) []*serr.WrapLink {
+	var links []*serr.WrapLink
+	for i := 0; ; i++ {
+		prefix := "wrap." + strconv.Itoa(i) + "."
+		msg, ok := metadata[prefix+"msg"]
+		if !ok {
+			break
+		}
+
+		link := &serr.WrapLink{Msg: msg, Preformatted: true}
+		if src, ok := metadata[prefix+"src"]; ok {
+			link.CallerLocation = &serr.Location{Preformatted: src}
+		}
+
+		for k, v := range metadata {
+			field := strings.TrimPrefix(k, prefix+"field.")
+			if field == k {
+				continue
+			}
+			if link.Fields == nil {
+				link.Fields = map[string]interface{}{}
+			}
+			link.Fields[field] = v
+		}
+
+		links = append(links, link)
+	}
+
+	return links
+}
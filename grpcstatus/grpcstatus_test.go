@@ -0,0 +1,114 @@
+package grpcstatus
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/MinamiKotoriCute/serr"
+)
+
+func TestStatusRoundTrip(t *testing.T) {
+	err := serr.Wraps(
+		serr.Errors(map[string]interface{}{"user": "alice"}, "user not found"),
+		map[string]interface{}{FieldGRPCCode: codes.NotFound, "request_id": "abc123"},
+		"lookup failed",
+	)
+
+	st, convErr := ToStatus(err)
+	if convErr != nil {
+		t.Fatal(convErr)
+	}
+	if st.Code() != codes.NotFound {
+		t.Fatalf("status code = %v, want %v", st.Code(), codes.NotFound)
+	}
+	if st.Message() != "lookup failed" {
+		t.Fatalf("status message = %q, want %q", st.Message(), "lookup failed")
+	}
+
+	rebuilt := FromStatus(st)
+	if rebuilt == nil {
+		t.Fatal("expected a non-nil rebuilt error")
+	}
+
+	s := serr.ToString(rebuilt, true)
+	if strings.Contains(s, "((") || strings.Contains(s, ":0(") {
+		t.Fatalf("expected remote frames to render like local ones, got mangled output: %q", s)
+	}
+	if !strings.Contains(s, "lookup failed") || !strings.Contains(s, "user not found") {
+		t.Fatalf("expected rebuilt error to contain both chain messages, got %q", s)
+	}
+	if !strings.Contains(s, "request_id") || !strings.Contains(s, "abc123") {
+		t.Fatalf("expected rebuilt error to contain the round-tripped fields, got %q", s)
+	}
+}
+
+func TestStatusRoundTripLiteralPercent(t *testing.T) {
+	// Msg is a printf-style format string (see toCustomString/toCustomJSON),
+	// so a literal '%' must be escaped as "%%" just like with fmt.Sprintf.
+	err := serr.New("memory at 90%% full")
+
+	st, convErr := ToStatus(err)
+	if convErr != nil {
+		t.Fatal(convErr)
+	}
+
+	rebuilt := FromStatus(st)
+	s := serr.ToString(rebuilt, false)
+	if !strings.Contains(s, "memory at 90% full") {
+		t.Fatalf("expected literal %% to survive the round trip uncorrupted, got %q", s)
+	}
+	if strings.Contains(s, "MISSING") {
+		t.Fatalf("expected no fmt.Sprintf verb-parsing artifacts from a second Sprintf pass, got %q", s)
+	}
+}
+
+func TestFromStatusOK(t *testing.T) {
+	if err := FromStatus(status.New(codes.OK, "")); err != nil {
+		t.Fatalf("expected nil for an OK status, got %v", err)
+	}
+	if err := FromStatus(nil); err != nil {
+		t.Fatalf("expected nil for a nil status, got %v", err)
+	}
+}
+
+func TestFromStatusNoDetails(t *testing.T) {
+	st := status.New(codes.NotFound, "plain grpc error")
+
+	err := FromStatus(st)
+	if err == nil {
+		t.Fatal("expected a non-nil error")
+	}
+	if !strings.Contains(err.Error(), "plain grpc error") {
+		t.Fatalf("Error() = %q, want it to contain %q", err.Error(), "plain grpc error")
+	}
+	if _, ok := err.(*remoteHierarchyError); ok {
+		t.Fatal("expected the detail-less fallback to be the plain status error, not a remoteHierarchyError")
+	}
+}
+
+func TestCodeFromFields(t *testing.T) {
+	cases := []struct {
+		name   string
+		fields map[string]interface{}
+		want   codes.Code
+		wantOk bool
+	}{
+		{"absent", nil, codes.Unknown, false},
+		{"codes.Code", map[string]interface{}{FieldGRPCCode: codes.PermissionDenied}, codes.PermissionDenied, true},
+		{"int", map[string]interface{}{FieldGRPCCode: int(5)}, codes.NotFound, true},
+		{"int32", map[string]interface{}{FieldGRPCCode: int32(5)}, codes.NotFound, true},
+		{"wrong type", map[string]interface{}{FieldGRPCCode: "not a code"}, codes.Unknown, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := codeFromFields(c.fields)
+			if got != c.want || ok != c.wantOk {
+				t.Fatalf("codeFromFields(%v) = (%v, %v), want (%v, %v)", c.fields, got, ok, c.want, c.wantOk)
+			}
+		})
+	}
+}
@@ -5,11 +5,10 @@ import (
 	"runtime"
 )
 
+// NewCallers captures the calling goroutine's stack with the default
+// StackCapturer (see SetDefaultCapturer).
 func NewCallers(skip int) []uintptr {
-	const depth = 64
-	var pcs [depth]uintptr
-	n := runtime.Callers(skip, pcs[:])
-	return pcs[0:n]
+	return getDefaultCapturer().Capture(skip + 1) // skip NewCallers
 }
 
 type FullStackError interface {
@@ -33,7 +32,16 @@ func (o *SimpleFullStackError) Error() string {
 
 func NewSimpleFullStackError(skip int) SimpleFullStackError {
 	return SimpleFullStackError{
-		callers: NewCallers(skip + 3), // skip NewSimpleFullStackError, NewCallers, runtime.Callers
+		callers: NewCallers(skip + 3), // skip NewSimpleFullStackError, NewCallers, runtime.Callers (NewCallers itself accounts for Capture)
+	}
+}
+
+func newSimpleFullStackErrorWithCapturer(capturer StackCapturer, skip int) SimpleFullStackError {
+	if capturer == nil {
+		capturer = getDefaultCapturer()
+	}
+	return SimpleFullStackError{
+		callers: capturer.Capture(skip + 3), // skip newSimpleFullStackErrorWithCapturer, Capture, runtime.Callers
 	}
 }
 
@@ -43,6 +51,7 @@ type ExtraStackData struct {
 	fields       map[string]interface{}
 	msg          string
 	msgArgs      []interface{}
+	code         *Code
 }
 
 func NewExtraStackData(skip int) *ExtraStackData {
@@ -114,8 +123,8 @@ type rootError struct {
 var _ FullStackError = (*rootError)(nil)
 var _ ExtraStackError = (*rootError)(nil)
 
-func New(msg string) error {
-	return ErrorDepthsWrapError(1, nil, nil, msg)
+func New(msg string, opts ...Option) error {
+	return errorDepthsWrapError(1, resolveCapturer(opts), nil, nil, msg)
 }
 
 func Errorf(msg string, args ...interface{}) error {
@@ -139,7 +148,11 @@ func ErrorDepths(skip int, fields map[string]interface{}, msg string, args ...in
 }
 
 func ErrorDepthsWrapError(skip int, err error, fields map[string]interface{}, msg string, args ...interface{}) error {
-	simpleFullStackErr := NewSimpleFullStackError(skip + 1) // skip ErrorDepthsWrapError
+	return errorDepthsWrapError(skip+1, nil, err, fields, msg, args...) // skip ErrorDepthsWrapError
+}
+
+func errorDepthsWrapError(skip int, capturer StackCapturer, err error, fields map[string]interface{}, msg string, args ...interface{}) error {
+	simpleFullStackErr := newSimpleFullStackErrorWithCapturer(capturer, skip+1) // skip errorDepthsWrapError
 	extraStackData := NewExtraStackDataFromCallers(simpleFullStackErr.Callers())
 	extraStackData.fields = fields
 	extraStackData.msg = msg
@@ -173,8 +186,8 @@ type wrapError struct {
 
 var _ ExtraStackError = (*wrapError)(nil)
 
-func Wrap(err error) error {
-	return WrapDepth(1, err)
+func Wrap(err error, opts ...Option) error {
+	return wrapDepths(1, resolveCapturer(opts), err, nil, "")
 }
 
 func Wrapf(err error, msg string, msgArgs ...interface{}) error {
@@ -194,8 +207,12 @@ func WrapDepthf(skip int, err error, msg string, msgArgs ...interface{}) error {
 }
 
 func WrapDepths(skip int, err error, fields map[string]interface{}, msg string, msgArgs ...interface{}) error {
+	return wrapDepths(skip+1, nil, err, fields, msg, msgArgs...) // skip WrapDepths
+}
+
+func wrapDepths(skip int, capturer StackCapturer, err error, fields map[string]interface{}, msg string, msgArgs ...interface{}) error {
 	if Cause(err) != nil {
-		extraStackData := NewExtraStackData(skip + 3) // skip WrapDepths, NewExtraStackData, runtime.Callers
+		extraStackData := NewExtraStackData(skip + 3) // skip wrapDepths, NewExtraStackData, runtime.Callers
 		extraStackData.fields = fields
 		extraStackData.msg = msg
 		extraStackData.msgArgs = msgArgs
@@ -208,7 +225,7 @@ func WrapDepths(skip int, err error, fields map[string]interface{}, msg string,
 		}
 	}
 
-	return ErrorDepthsWrapError(skip+1, err, nil, "")
+	return errorDepthsWrapError(skip+1, capturer, err, nil, "") // skip wrapDepths
 }
 
 func (e *wrapError) Error() string {
@@ -264,7 +281,7 @@ func JoinDepth(skip int, errs ...error) error {
 
 	e := &joinError{
 		SimpleFullStackError: SimpleFullStackError{
-			callers: NewCallers(skip + 3), // skip JoinDepth, NewCallers, runtime.Callers
+			callers: NewCallers(skip + 3), // skip JoinDepth, NewCallers, runtime.Callers (NewCallers itself accounts for Capture)
 		},
 		errs: make([]error, 0, n),
 	}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
 	"testing"
 )
 
@@ -12,7 +14,7 @@ var ErrAnother = errors.New("another")
 
 func f1(hasError bool) error {
 	if hasError {
-		return Wrapf(ErrSomething, map[string]interface{}{"f": "f1"}, "f1")
+		return Wraps(ErrSomething, map[string]interface{}{"f": "f1"}, "f1")
 	}
 
 	return nil
@@ -20,7 +22,7 @@ func f1(hasError bool) error {
 
 func f2(hasError bool) error {
 	if err := f1(hasError); err != nil {
-		return Wrapf(Wrapf(err, map[string]interface{}{"f": "f2", "order": 2}, "f2-2"), map[string]interface{}{"f": "f2", "order": 1}, "f2-1")
+		return Wraps(Wraps(err, map[string]interface{}{"f": "f2", "order": 2}, "f2-2"), map[string]interface{}{"f": "f2", "order": 1}, "f2-1")
 	}
 
 	return nil
@@ -38,7 +40,7 @@ func f3(hasError bool) error {
 
 func f4(hasError bool) error {
 	if err := f3(hasError); err != nil {
-		return Wrapf(err, map[string]interface{}{"f": "f4"}, "f4")
+		return Wraps(err, map[string]interface{}{"f": "f4"}, "f4")
 	}
 
 	return nil
@@ -80,3 +82,176 @@ func TestSerr2(t *testing.T) {
 
 	fmt.Print(ToString(err, true))
 }
+
+func countStackLines(hierarchy *UnpackHierarchy, predicate FramePredicate) int {
+	n := len(compactLocations(hierarchy.CallerLocations, predicate))
+	for _, sub := range hierarchy.SubHierarchies {
+		n += countStackLines(sub, predicate)
+	}
+
+	return n
+}
+
+func TestStackCompaction(t *testing.T) {
+	err := f5(true)
+	hierarchy := Unpack(err)
+
+	raw := countStackLines(hierarchy, func(*Location) bool { return true })
+	compacted := countStackLines(hierarchy, nil)
+
+	if compacted >= raw {
+		t.Fatalf("expected compaction to shrink stack depth, raw=%d compacted=%d", raw, compacted)
+	}
+}
+
+func TestDisabledCapturer(t *testing.T) {
+	err := New("just one", WithCapturer(DisabledCapturer()))
+
+	if got := err.Error(); got != "just one: " {
+		t.Fatalf("Error() = %q, want %q", got, "just one: ")
+	}
+
+	hierarchy := Unpack(err)
+	if len(hierarchy.Links) != 1 || fmt.Sprintf(hierarchy.Links[0].Msg, hierarchy.Links[0].MsgArgs...) != "just one" {
+		t.Fatalf("expected a single link with msg %q, got %+v", "just one", hierarchy.Links)
+	}
+
+	if got := ToString(err, false); got != "just one: " {
+		t.Fatalf("ToString() = %q, want %q", got, "just one: ")
+	}
+}
+
+func TestLogValue(t *testing.T) {
+	err := New("root")
+
+	lv, ok := err.(slog.LogValuer)
+	if !ok {
+		t.Fatal("expected err to implement slog.LogValuer")
+	}
+
+	var msg string
+	for _, attr := range lv.LogValue().Group() {
+		if attr.Key == "msg" {
+			msg = attr.Value.String()
+		}
+	}
+
+	if msg != "root" {
+		t.Fatalf("msg attr = %q, want %q", msg, "root")
+	}
+}
+
+func TestWrapCodedNoMessage(t *testing.T) {
+	const CodeNotFound Code = 1
+	const CodeInternal Code = 2
+	RegisterCode(CodeNotFound, "not_found")
+	RegisterCode(CodeInternal, "internal")
+
+	err := WrapCoded(NewCoded(CodeNotFound, "x"), CodeInternal)
+
+	hierarchy := Unpack(err)
+	if len(hierarchy.Links) != 2 {
+		t.Fatalf("expected 2 links, got %d: %+v", len(hierarchy.Links), hierarchy.Links)
+	}
+
+	outer := hierarchy.Links[0]
+	if outer.Code == nil || *outer.Code != CodeInternal {
+		t.Fatalf("expected outer link's code to be CodeInternal, got %+v", outer.Code)
+	}
+
+	if s := ToString(err, false); !strings.Contains(s, "internal") {
+		t.Fatalf("ToString() = %q, want it to contain %q", s, "internal")
+	}
+
+	if j := fmt.Sprint(ToJSON(err, false)); !strings.Contains(j, "internal") {
+		t.Fatalf("ToJSON() = %q, want it to contain %q", j, "internal")
+	}
+}
+
+func TestCodeOfAndIs(t *testing.T) {
+	const CodeNotFound Code = 101
+	const CodeInternal Code = 102
+	RegisterCode(CodeNotFound, "code_of_not_found")
+	RegisterCode(CodeInternal, "code_of_internal")
+
+	err := WrapCoded(NewCoded(CodeNotFound, "x"), CodeInternal)
+
+	if c, ok := CodeOf(err); !ok || c != CodeNotFound {
+		t.Fatalf("CodeOf() = (%v, %v), want (%v, true) (CodeOf returns the innermost code)", c, ok, CodeNotFound)
+	}
+
+	if !Is(err, CodeInternal) {
+		t.Fatal("expected Is(err, CodeInternal) to be true for the outer code")
+	}
+	if !Is(err, CodeNotFound) {
+		t.Fatal("expected Is(err, CodeNotFound) to be true for the inner code")
+	}
+	if !errors.Is(err, CodeInternal) {
+		t.Fatal("expected errors.Is(err, CodeInternal) to be true")
+	}
+	if Is(err, CodeInternal) != errors.Is(err, CodeInternal) {
+		t.Fatal("expected Is and errors.Is to agree")
+	}
+
+	const CodeJoined Code = 103
+	RegisterCode(CodeJoined, "code_of_joined")
+	joined := Join(NewCoded(CodeJoined, "joined"), ErrSomething)
+
+	if !Is(joined, CodeJoined) {
+		t.Fatal("expected Is to find a code inside a Join branch")
+	}
+}
+
+func TestFramePredicateSuppressesWrapLinkSrc(t *testing.T) {
+	err := f4(true)
+
+	predicate, perr := NewRegexFramePredicate(`serr\.f4$`)
+	if perr != nil {
+		t.Fatal(perr)
+	}
+
+	s := ToCustomString(err, NewDefaultStringFormat(FormatOptions{
+		LocationFormatFunc: DefaultLocationFormatFunc,
+		WithTrace:          true,
+		FramePredicate:     predicate,
+	}))
+	if strings.Contains(s, "serr.f4)") {
+		t.Fatalf("expected ToCustomString to suppress the wrap's own f4 frame, got %q", s)
+	}
+
+	j := fmt.Sprint(ToCustomJSON(err, NewDefaultJSONFormat(FormatOptions{
+		LocationFormatFunc: DefaultLocationFormatFunc,
+		WithTrace:          true,
+		FramePredicate:     predicate,
+	})))
+	if strings.Contains(j, "serr.f4)") {
+		t.Fatalf("expected ToCustomJSON to suppress the wrap's own f4 frame, got %q", j)
+	}
+}
+
+func deepCallBench(depth int, f func() error) error {
+	if depth <= 0 {
+		return f()
+	}
+	return deepCallBench(depth-1, f)
+}
+
+func benchmarkCapturer(b *testing.B, opts ...Option) {
+	for i := 0; i < b.N; i++ {
+		_ = deepCallBench(32, func() error {
+			return New("deep", opts...)
+		})
+	}
+}
+
+func BenchmarkNewDeepStack_FixedDepthCapturer(b *testing.B) {
+	benchmarkCapturer(b, WithCapturer(FixedDepthCapturer(64)))
+}
+
+func BenchmarkNewDeepStack_AdaptiveCapturer(b *testing.B) {
+	benchmarkCapturer(b, WithCapturer(AdaptiveCapturer()))
+}
+
+func BenchmarkNewDeepStack_DisabledCapturer(b *testing.B) {
+	benchmarkCapturer(b, WithCapturer(DisabledCapturer()))
+}
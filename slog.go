@@ -0,0 +1,173 @@
+package serr
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+func (e *rootError) LogValue() slog.Value {
+	return hierarchyLogValue(Unpack(e))
+}
+
+func (e *wrapError) LogValue() slog.Value {
+	return hierarchyLogValue(Unpack(e))
+}
+
+func (e *joinError) LogValue() slog.Value {
+	return hierarchyLogValue(Unpack(e))
+}
+
+// hierarchyLogValue mirrors toCustomJSON's shape (msg, stack, wrap, external,
+// join) but builds an slog.Value so log backends can index the fields
+// instead of only seeing a formatted string.
+func hierarchyLogValue(hierarchy *UnpackHierarchy) slog.Value {
+	attrs := []slog.Attr{slog.String("msg", hierarchyMsg(hierarchy))}
+
+	if len(hierarchy.CallerLocations) > 0 {
+		stack := make([]string, len(hierarchy.CallerLocations))
+		for i, loc := range hierarchy.CallerLocations {
+			stack[i] = DefaultLocationFormatFunc(loc)
+		}
+		attrs = append(attrs, slog.Any("stack", stack))
+	}
+
+	if len(hierarchy.Links) > 0 {
+		wraps := make([]slog.Value, len(hierarchy.Links))
+		for i, link := range hierarchy.Links {
+			wrapAttrs := []slog.Attr{slog.String("msg", formatLinkMsg(link))}
+			if len(link.Fields) != 0 {
+				fieldAttrs := make([]slog.Attr, 0, len(link.Fields))
+				for k, v := range link.Fields {
+					fieldAttrs = append(fieldAttrs, slog.Any(k, v))
+				}
+				wrapAttrs = append(wrapAttrs, slog.Any("fields", slog.GroupValue(fieldAttrs...)))
+			}
+			wraps[i] = slog.GroupValue(wrapAttrs...)
+		}
+		attrs = append(attrs, slog.Any("wrap", wraps))
+	}
+
+	if hierarchy.ErrExternal != nil {
+		attrs = append(attrs, slog.String("external", fmt.Sprint(hierarchy.ErrExternal)))
+	}
+
+	if len(hierarchy.SubHierarchies) > 0 {
+		joins := make([]slog.Value, len(hierarchy.SubHierarchies))
+		for i, sub := range hierarchy.SubHierarchies {
+			joins[i] = hierarchyLogValue(sub)
+		}
+		attrs = append(attrs, slog.Any("join", joins))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// hierarchyMsg renders this hierarchy level's own wrap messages and external
+// error (not its SubHierarchies, which hierarchyLogValue renders separately
+// under "join"), the same way toCustomString joins them with ErrorSep.
+func hierarchyMsg(hierarchy *UnpackHierarchy) string {
+	var parts []string
+
+	for _, link := range hierarchy.Links {
+		msg := formatLinkMsg(link)
+		if link.Code != nil {
+			msg += " [" + link.Code.Error() + "]"
+		}
+		parts = append(parts, msg)
+	}
+
+	if hierarchy.ErrExternal != nil {
+		parts = append(parts, fmt.Sprint(hierarchy.ErrExternal))
+	}
+
+	return strings.Join(parts, ": ")
+}
+
+// LogOptions controls how LogAttrs renders an error.
+type LogOptions struct {
+	// PromoteFields additionally emits every fields map from Errors/Wraps
+	// calls in the chain as top-level attres, so log backends that don't
+	// index nested groups can still query on them directly.
+	PromoteFields bool
+}
+
+// LogAttrs returns the slog attrs for err: an "err" attr holding the same
+// structure as LogValue, plus, when options.PromoteFields is set, the
+// user-supplied fields maps promoted to top-level attrs.
+func LogAttrs(err error, options LogOptions) []slog.Attr {
+	hierarchy := Unpack(err)
+	attrs := []slog.Attr{slog.Any("err", hierarchyLogValue(hierarchy))}
+
+	if options.PromoteFields {
+		attrs = append(attrs, promotedFieldAttrs(hierarchy)...)
+	}
+
+	return attrs
+}
+
+func promotedFieldAttrs(hierarchy *UnpackHierarchy) []slog.Attr {
+	var attrs []slog.Attr
+
+	for _, link := range hierarchy.Links {
+		for k, v := range link.Fields {
+			attrs = append(attrs, slog.Any(k, v))
+		}
+	}
+
+	for _, sub := range hierarchy.SubHierarchies {
+		attrs = append(attrs, promotedFieldAttrs(sub)...)
+	}
+
+	return attrs
+}
+
+// Handler wraps an slog.Handler and rewrites any attr whose value is an
+// error into the same structured form LogAttrs produces, so callers can log
+// errors with plain slog.Any/"err" calls and still get serr's msg/stack/wrap
+// breakdown instead of a flat string. This covers attrs on the record and
+// attrs added through WithAttrs; errors nested inside a group attr are not
+// rewritten.
+type Handler struct {
+	next slog.Handler
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	newRecord := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		newRecord.AddAttrs(wrapErrorAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, newRecord)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	wrapped := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		wrapped[i] = wrapErrorAttr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(wrapped)}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+func wrapErrorAttr(a slog.Attr) slog.Attr {
+	if err, ok := a.Value.Any().(error); ok {
+		return slog.Any(a.Key, hierarchyLogValue(Unpack(err)))
+	}
+
+	return a
+}